@@ -1,20 +1,120 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/pkg/openshifttests"
 	"github.com/openshift/origin/pkg/synthetictests"
 	"github.com/openshift/origin/pkg/test/ginkgo"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	"k8s.io/kubectl/pkg/util/templates"
 
 	_ "github.com/openshift/origin/test/extended"
 	_ "github.com/openshift/origin/test/extended/util/annotate/generated"
 )
 
+var alertAllowListPath = flag.String("alert-allow-list", "", "path to a YAML file excusing known-noisy alerts from failing PrometheusAlertsInvariant")
+
+func init() {
+	flag.StringVar(&openshifttests.AirgapMirror, "airgap-mirror", "", "registry to rewrite image pulls through for the openshift/airgap suite")
+	flag.StringVar(&openshifttests.AirgapPullSecretFile, "airgap-pull-secret", "", "path to a dockerconfigjson file with credentials for --airgap-mirror")
+}
+
+var (
+	flakeDBSource = flag.String("flake-db", "", "path or URL to a JSON known-flakes registry consulted before a failed test is reported")
+	noFlakeRetry  = flag.Bool("no-flake-retry", false, "disable flake-db retries even if --flake-db is set; for release-gating jobs")
+)
+
+var (
+	shardIndex = flag.Int("shard-index", -1, "0-based index of the shard to run; requires --shard-count")
+	shardCount = flag.Int("shard-count", 0, "number of shards to deterministically partition each suite's matching tests across")
+	printShard = flag.Bool("print-shard", false, "log the shard each matching test falls into; combine with --dry-run to preview the partition without running anything")
+)
+
+// applyShard wires --shard-index/--shard-count/--print-shard into every
+// suite's Matches function so each of the N independent invocations of a
+// sharded suite only runs its own fraction of the matching tests. It's a
+// no-op unless --shard-count is set.
+func applyShard(suites openshifttests.TestSuites) error {
+	if *shardCount == 0 {
+		return nil
+	}
+	opts := openshifttests.ShardOptions{
+		ShardIndex: *shardIndex,
+		ShardCount: *shardCount,
+	}
+	if *printShard {
+		opts.PrintShard = func(name string, shard int) {
+			fmt.Fprintf(os.Stdout, "shard %d: %s\n", shard, name)
+		}
+	}
+	return suites.ApplyShard(opts)
+}
+
+// flakeClassifier builds the FlakeClassifier requested by --flake-db,
+// respecting --no-flake-retry. It stays nil (no retries) unless --flake-db
+// is set, mirroring how alertAllowList stays nil unless --alert-allow-list
+// is set.
+func flakeClassifier() (ginkgo.FlakeClassifier, error) {
+	if *flakeDBSource == "" || *noFlakeRetry {
+		return nil, nil
+	}
+	return ginkgo.LoadFlakeClassifier(*flakeDBSource)
+}
+
 func main() {
-	openshifttests.Main(staticSuites)
+	flag.Parse()
+	classifier, err := flakeClassifier()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading --flake-db: %v\n", err)
+		os.Exit(1)
+	}
+	if err := applyShard(staticSuites); err != nil {
+		fmt.Fprintf(os.Stderr, "error applying --shard-index/--shard-count: %v\n", err)
+		os.Exit(1)
+	}
+	openshifttests.Main(staticSuites, openshifttests.WithFlakeClassifier(classifier))
+}
+
+var (
+	alertAllowListOnce sync.Once
+	alertAllowList     *synthetictests.AlertAllowList
+)
+
+// withAlerts augments an existing system event invariant with
+// PrometheusAlertsInvariant, so every suite that already fails on unstable
+// system events also fails on an alert that fired without an allow-list
+// entry. The allow-list is loaded lazily, on first suite run, since
+// staticSuites is built at package initialization, before main calls
+// flag.Parse.
+func withAlerts(base synthetictests.EventIntervalTest) ginkgo.SyntheticEventTest {
+	return ginkgo.JUnitForEventsFunc(func(events monitorapi.Intervals, duration time.Duration) []*junitapi.JUnitTestCase {
+		alertAllowListOnce.Do(func() {
+			if *alertAllowListPath == "" {
+				return
+			}
+			list, err := synthetictests.LoadAlertAllowList(*alertAllowListPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error loading --alert-allow-list: %v\n", err)
+				return
+			}
+			alertAllowList = list
+		})
+		sidecarPath := ""
+		if artifactDir := os.Getenv("ARTIFACT_DIR"); artifactDir != "" {
+			sidecarPath = artifactDir + "/alerts.json"
+		}
+		return synthetictests.CombineEventTests(
+			base,
+			synthetictests.PrometheusAlertsInvariant(alertAllowList, sidecarPath),
+		)(events, duration)
+	})
 }
 
 // staticSuites are all known test suites this binary should run
@@ -32,7 +132,7 @@ var staticSuites = openshifttests.TestSuites{
 				return strings.Contains(name, "[Suite:openshift/conformance/")
 			},
 			Parallelism:         30,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -48,9 +148,12 @@ var staticSuites = openshifttests.TestSuites{
 				}
 				return strings.Contains(name, "[Suite:openshift/conformance/parallel")
 			},
-			Parallelism:          30,
+			Parallelism: 30,
+			// Kept as a backstop for flakes --flake-db doesn't know about
+			// yet; a matched entry there takes the test out of this count
+			// entirely by retrying it instead of just tolerating the fail.
 			MaximumAllowedFlakes: 15,
-			SyntheticEventTests:  ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests:  withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -67,7 +170,7 @@ var staticSuites = openshifttests.TestSuites{
 				return strings.Contains(name, "[Suite:openshift/conformance/serial") || openshifttests.IsStandardEarlyOrLateTest(name)
 			},
 			TestTimeout:         40 * time.Minute,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -91,9 +194,10 @@ var staticSuites = openshifttests.TestSuites{
 			},
 			// Duration of the quorum restore test exceeds 60 minutes.
 			TestTimeout:         90 * time.Minute,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.SystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.SystemEventInvariants),
 		},
-		PreSuite: openshifttests.SuiteWithProviderPreSuite,
+		PreSuite:    openshifttests.SuiteWithProviderPreSuite,
+		PostFailure: openshifttests.MustGatherPostFailure(""),
 	},
 	{
 		TestSuite: ginkgo.TestSuite{
@@ -108,7 +212,7 @@ var staticSuites = openshifttests.TestSuites{
 				return strings.Contains(name, "[Suite:k8s]") && strings.Contains(name, "[Conformance]")
 			},
 			Parallelism:         30,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -126,10 +230,11 @@ var staticSuites = openshifttests.TestSuites{
 			},
 			Parallelism: 7,
 			// TODO: Builds are really flaky right now, remove when we land perf updates and fix io on workers
+			// Same backstop role as the parallel suite's MaximumAllowedFlakes: --flake-db should shrink how often this is actually hit.
 			MaximumAllowedFlakes: 3,
 			// Jenkins tests can take a really long time
 			TestTimeout:         60 * time.Minute,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -146,7 +251,7 @@ var staticSuites = openshifttests.TestSuites{
 				return strings.Contains(name, "[Feature:Templates]") || openshifttests.IsStandardEarlyOrLateTest(name)
 			},
 			Parallelism:         1,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -162,7 +267,7 @@ var staticSuites = openshifttests.TestSuites{
 				}
 				return strings.Contains(name, "[sig-imageregistry]") || openshifttests.IsStandardEarlyOrLateTest(name)
 			},
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -180,7 +285,7 @@ var staticSuites = openshifttests.TestSuites{
 			},
 			Parallelism:         7,
 			TestTimeout:         20 * time.Minute,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -198,7 +303,7 @@ var staticSuites = openshifttests.TestSuites{
 			},
 			Parallelism:         4,
 			TestTimeout:         20 * time.Minute,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -216,7 +321,7 @@ var staticSuites = openshifttests.TestSuites{
 			},
 			Parallelism:         4,
 			TestTimeout:         20 * time.Minute,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -249,7 +354,7 @@ var staticSuites = openshifttests.TestSuites{
 				}
 				return !strings.Contains(name, "[Suite:openshift/conformance/")
 			},
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -265,7 +370,7 @@ var staticSuites = openshifttests.TestSuites{
 				}
 				return strings.Contains(name, "[Feature:LegacyCommandTests]") || openshifttests.IsStandardEarlyOrLateTest(name)
 			},
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithNoProviderPreSuite,
 	},
@@ -290,7 +395,7 @@ var staticSuites = openshifttests.TestSuites{
 
 				return strings.Contains(name, "External Storage [Driver:") && !strings.Contains(name, "[Disruptive]")
 			},
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithKubeTestInitializationPreSuite,
 		PostSuite: func(opt *openshifttests.RunOptions) {
@@ -320,7 +425,7 @@ var staticSuites = openshifttests.TestSuites{
 			Parallelism:         60,
 			Count:               12,
 			TestTimeout:         20 * time.Minute,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithProviderPreSuite,
 	},
@@ -355,7 +460,7 @@ var staticSuites = openshifttests.TestSuites{
 			},
 			Parallelism:          20,
 			MaximumAllowedFlakes: 15,
-			SyntheticEventTests:  ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests:  withAlerts(synthetictests.StableSystemEventInvariants),
 		},
 		PreSuite: openshifttests.SuiteWithKubeTestInitializationPreSuite,
 	},
@@ -385,9 +490,10 @@ var staticSuites = openshifttests.TestSuites{
 			},
 			// etcd's vertical scaling test can take a while for apiserver rollouts to stabilize on the same revision
 			TestTimeout:         60 * time.Minute,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.StableSystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
 		},
-		PreSuite: openshifttests.SuiteWithProviderPreSuite,
+		PreSuite:    openshifttests.SuiteWithProviderPreSuite,
+		PostFailure: openshifttests.MustGatherPostFailure(""),
 	},
 	{
 		TestSuite: ginkgo.TestSuite{
@@ -403,9 +509,10 @@ var staticSuites = openshifttests.TestSuites{
 			},
 			// etcd's restore test can take a while for apiserver rollouts to stabilize
 			TestTimeout:         120 * time.Minute,
-			SyntheticEventTests: ginkgo.JUnitForEventsFunc(synthetictests.SystemEventInvariants),
+			SyntheticEventTests: withAlerts(synthetictests.SystemEventInvariants),
 		},
-		PreSuite: openshifttests.SuiteWithProviderPreSuite,
+		PreSuite:    openshifttests.SuiteWithProviderPreSuite,
+		PostFailure: openshifttests.MustGatherPostFailure(""),
 	},
 	{
 		TestSuite: ginkgo.TestSuite{
@@ -421,6 +528,29 @@ var staticSuites = openshifttests.TestSuites{
 			},
 			TestTimeout: 30 * time.Minute,
 		},
-		PreSuite: openshifttests.SuiteWithProviderPreSuite,
+		PreSuite:    openshifttests.SuiteWithProviderPreSuite,
+		PostFailure: openshifttests.MustGatherPostFailure(""),
+	},
+	{
+		TestSuite: ginkgo.TestSuite{
+			Name: "openshift/airgap",
+			Description: templates.LongDesc(`
+		Runs the image-ecosystem, build, image-registry, and jenkins-e2e suites against a mirror registry so they can be exercised on a disconnected-style cluster. Requires --airgap-mirror.
+		`),
+			Matches: func(name string) bool {
+				if openshifttests.IsDisabled(name) {
+					return false
+				}
+				if strings.Contains(name, "[Feature:InternetAccess]") {
+					return false
+				}
+				return strings.Contains(name, "[Feature:Builds]") || strings.Contains(name, "[sig-imageregistry]") ||
+					strings.Contains(name, "[Feature:ImageEcosystem]") || strings.Contains(name, "[Feature:Jenkins]") ||
+					openshifttests.IsStandardEarlyOrLateTest(name)
+			},
+			Parallelism:         7,
+			SyntheticEventTests: withAlerts(synthetictests.StableSystemEventInvariants),
+		},
+		PreSuite: openshifttests.AirgapPreSuite,
 	},
 }
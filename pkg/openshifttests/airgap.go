@@ -0,0 +1,147 @@
+package openshifttests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	exutil "github.com/openshift/origin/test/extended/util"
+)
+
+// AirgapMirror is the registry that AirgapPreSuite rewrites image pull
+// references through, set by the --airgap-mirror flag. It is a package
+// variable, rather than a RunOptions field, so PreSuite funcs that don't
+// know about airgap keep their existing signature.
+var AirgapMirror string
+
+// AirgapPullSecretFile points at a dockerconfigjson file with credentials
+// for AirgapMirror, set by the --airgap-pull-secret flag. Required unless
+// the mirror allows anonymous pulls.
+var AirgapPullSecretFile string
+
+// airgapMirroredSources are the upstream registries the image-ecosystem,
+// build, image-registry, and jenkins-e2e suites otherwise assume are
+// reachable.
+var airgapMirroredSources = []string{"registry.redhat.io", "quay.io"}
+
+// AirgapPreSuite prepares the openshift/airgap suite, the suite that
+// registers it as its PreSuite, to exercise the image-ecosystem, build,
+// image-registry, and jenkins-e2e tests against a disconnected-style
+// cluster: it verifies the mirror is reachable, installs an
+// ImageDigestMirrorSet so the cluster rewrites pulls from
+// registry.redhat.io/quay.io through the mirror at the node level, and,
+// when AirgapPullSecretFile is set, injects a pull secret for the mirror
+// into the test namespace's default service account.
+func AirgapPreSuite(opt *RunOptions) error {
+	if err := SuiteWithProviderPreSuite(opt); err != nil {
+		return err
+	}
+
+	if AirgapMirror == "" {
+		return fmt.Errorf("--airgap-mirror must be set to run the openshift/airgap suite")
+	}
+	if err := checkMirrorReachable(AirgapMirror); err != nil {
+		return fmt.Errorf("mirror registry %s is not reachable: %w", AirgapMirror, err)
+	}
+
+	configClient, err := configclient.NewForConfig(exutil.TestContext.KubeConfig)
+	if err != nil {
+		return fmt.Errorf("building config client for airgap image mirroring: %w", err)
+	}
+	if err := ensureImageDigestMirrorSet(configClient, AirgapMirror); err != nil {
+		return fmt.Errorf("installing ImageDigestMirrorSet for %s: %w", AirgapMirror, err)
+	}
+
+	client, err := kubernetes.NewForConfig(exutil.TestContext.KubeConfig)
+	if err != nil {
+		return fmt.Errorf("building client for airgap pull secret injection: %w", err)
+	}
+	return injectMirrorPullSecret(client, opt.Namespace, AirgapMirror)
+}
+
+// checkMirrorReachable fails fast, before spending test time on a cluster
+// that can't actually pull from the configured mirror.
+func checkMirrorReachable(mirror string) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get("https://" + mirror + "/v2/")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// ensureImageDigestMirrorSet installs (or reuses) an ImageDigestMirrorSet
+// that tells every node's CRI-O to resolve pulls from
+// airgapMirroredSources through mirror instead, regardless of what
+// registry hostname an individual pod manifest references. This is what
+// actually makes the image-ecosystem/build/image-registry/jenkins-e2e
+// suites disconnected-safe, rather than relying on every test fixture
+// being rewritten by hand.
+func ensureImageDigestMirrorSet(client configclient.Interface, mirror string) error {
+	mirrors := make([]configv1.ImageDigestMirrors, 0, len(airgapMirroredSources))
+	for _, source := range airgapMirroredSources {
+		mirrors = append(mirrors, configv1.ImageDigestMirrors{
+			Source:  source,
+			Mirrors: []configv1.ImageMirror{configv1.ImageMirror(mirror)},
+		})
+	}
+
+	idms := &configv1.ImageDigestMirrorSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "openshift-tests-airgap"},
+		Spec:       configv1.ImageDigestMirrorSetSpec{ImageDigestMirrors: mirrors},
+	}
+	_, err := client.ConfigV1().ImageDigestMirrorSets().Create(context.TODO(), idms, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// injectMirrorPullSecret creates a dockerconfigjson secret from
+// AirgapPullSecretFile and attaches it to the test namespace's default
+// service account, so image pulls rewritten through mirror by the
+// ImageDigestMirrorSet also carry the credentials it requires.
+func injectMirrorPullSecret(client kubernetes.Interface, namespace, mirror string) error {
+	const secretName = "airgap-mirror-pull-secret"
+
+	if AirgapPullSecretFile == "" {
+		return nil
+	}
+
+	dockerConfigJSON, err := os.ReadFile(AirgapPullSecretFile)
+	if err != nil {
+		return fmt.Errorf("reading --airgap-pull-secret: %w", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: dockerConfigJSON},
+	}
+	if _, err := client.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("creating %s in %s: %w", secretName, namespace, err)
+	}
+
+	sa, err := client.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), "default", metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("fetching default service account in %s: %w", namespace, err)
+	}
+
+	for _, ref := range sa.ImagePullSecrets {
+		if ref.Name == secretName {
+			return nil
+		}
+	}
+	sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: secretName})
+	_, err = client.CoreV1().ServiceAccounts(namespace).Update(context.TODO(), sa, metav1.UpdateOptions{})
+	return err
+}
@@ -63,6 +63,21 @@ type TestSuite struct {
 	PostSuite func(opt *RunOptions)
 
 	PreTest func() error
+
+	// PostFailure, when set, is a hook Main's suite-running loop invokes
+	// once, after the suite finishes, whenever at least one test failed.
+	// It is meant for suites where post-mortem cluster state is what
+	// actually diagnoses the flake (e.g. etcd recovery), so the cost of
+	// gathering it isn't paid on suites that rarely fail. Main itself
+	// lives outside this file; see MustGatherPostFailure for a built-in
+	// implementation suites can register.
+	PostFailure func(opt *RunOptions, failed []ginkgo.TestResult) error
+
+	// JUnitSuffix, when set, is appended to the JUnit filename Main writes
+	// for this suite (e.g. "-shard-0-of-4"), so that N independent sharded
+	// invocations of the same suite don't overwrite each other's results
+	// and a downstream job can merge them back together.
+	JUnitSuffix string
 }
 
 type TestSuites []TestSuite
@@ -0,0 +1,131 @@
+package openshifttests
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/openshift/origin/pkg/test/ginkgo"
+)
+
+// DefaultMustGatherImage is used by MustGatherPostFailure when no image is
+// configured, matching the image `oc adm must-gather` itself defaults to.
+const DefaultMustGatherImage = "registry.redhat.io/openshift4/ose-must-gather:latest"
+
+// MustGatherPostFailure is a TestSuite.PostFailure implementation that
+// captures `oc adm must-gather` output alongside the suite's JUnit XML in
+// $ARTIFACT_DIR. It's registered on suites where the failures are rare but
+// expensive to reproduce, so the extra minutes of cluster state collection
+// are worth paying on every non-zero exit. Main's suite-running loop is
+// what actually calls the returned func after a suite fails.
+func MustGatherPostFailure(image string) func(opt *RunOptions, failed []ginkgo.TestResult) error {
+	if image == "" {
+		image = DefaultMustGatherImage
+	}
+
+	return func(opt *RunOptions, failed []ginkgo.TestResult) error {
+		if len(failed) == 0 {
+			return nil
+		}
+		artifactDir := os.Getenv("ARTIFACT_DIR")
+		if artifactDir == "" {
+			return fmt.Errorf("ARTIFACT_DIR must be set to collect a must-gather bundle")
+		}
+
+		gatherDir, err := os.MkdirTemp("", "must-gather-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(gatherDir)
+
+		cmd := exec.Command("oc", "adm", "must-gather", fmt.Sprintf("--image=%s", image), fmt.Sprintf("--dest-dir=%s", gatherDir))
+		cmd.Env = mustGatherEnv(opt.Kubeconfig)
+		cmd.Stdout = opt.Out
+		cmd.Stderr = opt.ErrOut
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("oc adm must-gather failed: %w", err)
+		}
+
+		return tarGzDir(gatherDir, filepath.Join(artifactDir, "must-gather.tar.gz"))
+	}
+}
+
+// mustGatherEnv returns os.Environ() with KUBECONFIG overridden to
+// kubeconfig when it is set, so must-gather can use admin credentials even
+// when the test run itself was given a scoped-down kubeconfig.
+func mustGatherEnv(kubeconfig string) []string {
+	env := os.Environ()
+	if kubeconfig == "" {
+		return env
+	}
+	filtered := env[:0]
+	for _, kv := range env {
+		if len(kv) < 11 || kv[:11] != "KUBECONFIG=" {
+			filtered = append(filtered, kv)
+		}
+	}
+	return append(filtered, "KUBECONFIG="+kubeconfig)
+}
+
+func tarGzDir(srcDir, destFile string) error {
+	out, err := os.Create(destFile)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	walkErr := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = relPath
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+
+	// tw and gz are flushed on Close, so a write failure (e.g. the disk
+	// filling up) only surfaces here, not on the earlier WriteHeader/Copy
+	// calls. A bare defer would discard that error and let the caller
+	// believe a truncated tarball was written successfully.
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing tar writer: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("closing gzip writer: %w", err)
+	}
+	return nil
+}
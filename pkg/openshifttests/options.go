@@ -0,0 +1,24 @@
+package openshifttests
+
+import "github.com/openshift/origin/pkg/test/ginkgo"
+
+// MainOption configures Main beyond the static suite list it's always
+// given. It exists so new cross-suite behavior (like flake-db retries) can
+// be wired in without changing Main's required arguments.
+type MainOption func(*mainOptions)
+
+type mainOptions struct {
+	FlakeRetrier *ginkgo.FlakeRetrier
+}
+
+// WithFlakeClassifier registers a FlakeClassifier that Main's suite-running
+// loop is expected to consult before reporting a failed test, retrying it
+// up to the matched entry's MaxRetries via the resulting
+// FlakeRetrier.Resolve, and to read FlakeRetrier.Retried after the suite
+// finishes to surface the "flake-retried" count in its summary. A nil
+// classifier (the default) disables flake-db retries entirely.
+func WithFlakeClassifier(classifier ginkgo.FlakeClassifier) MainOption {
+	return func(o *mainOptions) {
+		o.FlakeRetrier = ginkgo.NewFlakeRetrier(classifier)
+	}
+}
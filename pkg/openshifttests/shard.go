@@ -0,0 +1,74 @@
+package openshifttests
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// ShardOptions configures running a fraction of a suite's matching tests so
+// that a long-running suite (e.g. openshift/etcd/recovery at 120m) can be
+// fanned out across N independent invocations in CI. ShardIndex is 0-based
+// and must be less than ShardCount.
+type ShardOptions struct {
+	ShardIndex int
+	ShardCount int
+
+	// PrintShard, when set, is called by ShardMatchFn with the shard of every
+	// test the underlying Matches accepts, including tests outside
+	// ShardIndex; the shard filter itself still applies. Combined with
+	// --dry-run this lets a user preview the partition before committing CI
+	// minutes to it.
+	PrintShard func(name string, shard int)
+}
+
+// testNameShard deterministically assigns name to a shard in [0, count)
+// using fnv64 so the same test always lands in the same shard across
+// reruns and across the N separate openshift-tests invocations that make
+// up a sharded suite run.
+func testNameShard(name string, count int) int {
+	h := fnv.New64a()
+	// Hash.Write never returns an error.
+	_, _ = h.Write([]byte(name))
+	return int(h.Sum64() % uint64(count))
+}
+
+// ShardMatchFn wraps an existing suite Matches function so it additionally
+// requires a test to hash into the configured shard. It validates opts up
+// front so a misconfigured --shard-index/--shard-count pair fails fast
+// rather than silently running zero or every test.
+func ShardMatchFn(matches func(name string) bool, opts ShardOptions) (func(name string) bool, error) {
+	if opts.ShardCount <= 0 {
+		return nil, fmt.Errorf("shard count must be positive, got %d", opts.ShardCount)
+	}
+	if opts.ShardIndex < 0 || opts.ShardIndex >= opts.ShardCount {
+		return nil, fmt.Errorf("shard index %d out of range [0, %d)", opts.ShardIndex, opts.ShardCount)
+	}
+
+	return func(name string) bool {
+		if !matches(name) {
+			return false
+		}
+		shard := testNameShard(name, opts.ShardCount)
+		if opts.PrintShard != nil {
+			opts.PrintShard(name, shard)
+		}
+		return shard == opts.ShardIndex
+	}, nil
+}
+
+// ApplyShard wraps every suite's Matches function with ShardMatchFn and
+// sets JUnitSuffix so the suites in place can be run as one of N shards.
+// Suites themselves don't need to know about sharding; a caller (the CLI's
+// --shard-index/--shard-count flags) applies it across the board.
+func (s TestSuites) ApplyShard(opts ShardOptions) error {
+	suffix := fmt.Sprintf("-shard-%d-of-%d", opts.ShardIndex, opts.ShardCount)
+	for i := range s {
+		sharded, err := ShardMatchFn(s[i].Matches, opts)
+		if err != nil {
+			return fmt.Errorf("suite %s: %w", s[i].Name, err)
+		}
+		s[i].Matches = sharded
+		s[i].JUnitSuffix += suffix
+	}
+	return nil
+}
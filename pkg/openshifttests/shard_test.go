@@ -0,0 +1,112 @@
+package openshifttests
+
+import "testing"
+
+func TestTestNameShardIsStable(t *testing.T) {
+	name := "[sig-auth] should create a token"
+	first := testNameShard(name, 4)
+	for i := 0; i < 10; i++ {
+		if got := testNameShard(name, 4); got != first {
+			t.Fatalf("testNameShard(%q, 4) = %d on call %d, want stable %d", name, got, i, first)
+		}
+	}
+}
+
+func TestTestNameShardDistributesAcrossShards(t *testing.T) {
+	const count = 4
+	names := []string{
+		"[sig-auth] should create a token",
+		"[sig-storage] should mount a volume",
+		"[sig-network] should route a packet",
+		"[sig-node] should schedule a pod",
+		"[sig-api-machinery] should list resources",
+		"[sig-builds] should run a build",
+		"[sig-imageregistry] should push an image",
+		"[sig-cli] should parse flags",
+	}
+
+	seen := make(map[int]bool)
+	for _, name := range names {
+		shard := testNameShard(name, count)
+		if shard < 0 || shard >= count {
+			t.Fatalf("testNameShard(%q, %d) = %d, want [0, %d)", name, count, shard, count)
+		}
+		seen[shard] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected the sample names to spread across more than one shard, all landed in %v", seen)
+	}
+}
+
+func TestShardMatchFnRejectsNonPositiveShardCount(t *testing.T) {
+	for _, count := range []int{0, -1} {
+		if _, err := ShardMatchFn(func(string) bool { return true }, ShardOptions{ShardIndex: 0, ShardCount: count}); err == nil {
+			t.Errorf("ShardMatchFn with ShardCount %d: expected error, got nil", count)
+		}
+	}
+}
+
+func TestShardMatchFnRejectsOutOfRangeShardIndex(t *testing.T) {
+	for _, index := range []int{-1, 2, 3} {
+		if _, err := ShardMatchFn(func(string) bool { return true }, ShardOptions{ShardIndex: index, ShardCount: 2}); err == nil {
+			t.Errorf("ShardMatchFn with ShardIndex %d (ShardCount 2): expected error, got nil", index)
+		}
+	}
+}
+
+func TestShardMatchFnPartitionsMatches(t *testing.T) {
+	const count = 3
+	names := []string{
+		"[sig-auth] should create a token",
+		"[sig-storage] should mount a volume",
+		"[sig-network] should route a packet",
+		"[sig-node] should schedule a pod",
+		"[sig-api-machinery] should list resources",
+		"[sig-builds] should run a build",
+	}
+
+	matched := make(map[string]int)
+	for shard := 0; shard < count; shard++ {
+		matchFn, err := ShardMatchFn(func(string) bool { return true }, ShardOptions{ShardIndex: shard, ShardCount: count})
+		if err != nil {
+			t.Fatalf("ShardMatchFn returned error: %v", err)
+		}
+		for _, name := range names {
+			if matchFn(name) {
+				matched[name]++
+			}
+		}
+	}
+
+	for _, name := range names {
+		if matched[name] != 1 {
+			t.Errorf("name %q matched %d shards out of %d, want exactly 1", name, matched[name], count)
+		}
+	}
+}
+
+func TestShardMatchFnRespectsUnderlyingMatches(t *testing.T) {
+	matchFn, err := ShardMatchFn(func(name string) bool { return false }, ShardOptions{ShardIndex: 0, ShardCount: 2})
+	if err != nil {
+		t.Fatalf("ShardMatchFn returned error: %v", err)
+	}
+	if matchFn("[sig-auth] should create a token") {
+		t.Errorf("expected ShardMatchFn to defer to a non-matching underlying Matches func")
+	}
+}
+
+func TestShardMatchFnCallsPrintShard(t *testing.T) {
+	var printed []int
+	matchFn, err := ShardMatchFn(func(string) bool { return true }, ShardOptions{
+		ShardIndex: 0,
+		ShardCount: 2,
+		PrintShard: func(name string, shard int) { printed = append(printed, shard) },
+	})
+	if err != nil {
+		t.Fatalf("ShardMatchFn returned error: %v", err)
+	}
+	matchFn("[sig-auth] should create a token")
+	if len(printed) != 1 {
+		t.Fatalf("expected PrintShard to be called once, got %d calls", len(printed))
+	}
+}
@@ -0,0 +1,160 @@
+package synthetictests
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// AlertAllowListEntry excuses a single alert from failing
+// PrometheusAlertsInvariant. Label matchers are optional regular
+// expressions; an empty matcher matches any value.
+type AlertAllowListEntry struct {
+	AlertName     string            `yaml:"alertname"`
+	LabelMatchers map[string]string `yaml:"labelMatchers,omitempty"`
+	MaxDuration   time.Duration     `yaml:"maxDuration,omitempty"`
+	Justification string            `yaml:"justification"`
+
+	compiled map[string]*regexp.Regexp
+}
+
+// AlertAllowList is the parsed form of the --alert-allow-list YAML file.
+type AlertAllowList struct {
+	Entries []AlertAllowListEntry `yaml:"entries"`
+}
+
+// LoadAlertAllowList reads and parses the YAML file at path.
+func LoadAlertAllowList(path string) (*AlertAllowList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading alert allow-list %s: %w", path, err)
+	}
+	var list AlertAllowList
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parsing alert allow-list %s: %w", path, err)
+	}
+	for i := range list.Entries {
+		if err := list.Entries[i].compile(); err != nil {
+			return nil, fmt.Errorf("alert allow-list %s, entry %d: %w", path, i, err)
+		}
+	}
+	return &list, nil
+}
+
+func (e *AlertAllowListEntry) compile() error {
+	e.compiled = make(map[string]*regexp.Regexp, len(e.LabelMatchers))
+	for label, pattern := range e.LabelMatchers {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("label matcher %s=%q: %w", label, pattern, err)
+		}
+		e.compiled[label] = re
+	}
+	return nil
+}
+
+// allows reports whether this entry excuses the given firing/pending alert
+// sample. It returns only that verdict; the entry's Justification is not
+// used here or rendered into the JUnit output. duration is how long this specific alert was observed firing/pending,
+// not the suite's overall run time.
+func (e *AlertAllowListEntry) allows(alert monitorapi.Alert, duration time.Duration) bool {
+	if alert.Name != e.AlertName {
+		return false
+	}
+	if e.MaxDuration > 0 && duration > e.MaxDuration {
+		return false
+	}
+	for label, re := range e.compiled {
+		if !re.MatchString(alert.Labels[label]) {
+			return false
+		}
+	}
+	return true
+}
+
+// PrometheusAlertsInvariant reads every alert that fired or was pending
+// during the suite from the alert intervals in events (via
+// monitorapi.AlertsFromIntervals); it does not query Prometheus itself. It
+// fails unless each alert is excused by an entry in allowList. Samples are written
+// to sidecarPath (a JSON file next to junit.xml) regardless of outcome so
+// downstream tooling can trend alert noise across runs.
+func PrometheusAlertsInvariant(allowList *AlertAllowList, sidecarPath string) EventIntervalTest {
+	return func(events monitorapi.Intervals, _ time.Duration) []*junitapi.JUnitTestCase {
+		// Each alert is checked against its own observed firing/pending
+		// duration below, not the suite's overall run time.
+		const testName = "[sig-arch] Alerts should only fire for allow-listed reasons"
+
+		alerts := monitorapi.AlertsFromIntervals(events)
+		if err := writeAlertSamples(sidecarPath, alerts); err != nil {
+			// Failing to write the sidecar shouldn't mask the actual
+			// test result, but it is worth surfacing.
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+
+		var unexpected []string
+		for _, alert := range alerts {
+			alertDuration := alert.To.Sub(alert.From)
+			if alertIsAllowed(allowList, alert, alertDuration) {
+				continue
+			}
+			unexpected = append(unexpected, fmt.Sprintf("alert %s fired with labels %v for %s", alert.Name, alert.Labels, alertDuration))
+		}
+
+		if len(unexpected) == 0 {
+			return []*junitapi.JUnitTestCase{{Name: testName}}
+		}
+
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: testName,
+				FailureOutput: &junitapi.FailureOutput{
+					Output: fmt.Sprintf("%d alert(s) fired without an allow-list entry:\n%s", len(unexpected), joinLines(unexpected)),
+				},
+			},
+		}
+	}
+}
+
+func alertIsAllowed(allowList *AlertAllowList, alert monitorapi.Alert, duration time.Duration) bool {
+	if allowList == nil {
+		return false
+	}
+	for i := range allowList.Entries {
+		if allowList.Entries[i].allows(alert, duration) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAlertSamples records the raw alert samples observed during the
+// suite as JSON next to junit.xml so trends can be tracked across runs
+// without re-parsing the JUnit failure text.
+func writeAlertSamples(sidecarPath string, alerts []monitorapi.Alert) error {
+	if sidecarPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(alerts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling alert samples: %w", err)
+	}
+	if err := os.WriteFile(sidecarPath, data, 0o644); err != nil {
+		return fmt.Errorf("writing alert samples to %s: %w", sidecarPath, err)
+	}
+	return nil
+}
+
+func joinLines(lines []string) string {
+	out := ""
+	for _, line := range lines {
+		out += "  " + line + "\n"
+	}
+	return out
+}
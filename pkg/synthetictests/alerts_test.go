@@ -0,0 +1,110 @@
+package synthetictests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func mustCompile(t *testing.T, e AlertAllowListEntry) AlertAllowListEntry {
+	t.Helper()
+	if err := e.compile(); err != nil {
+		t.Fatalf("compile() returned error: %v", err)
+	}
+	return e
+}
+
+func TestAlertAllowListEntryAllowsMatchesName(t *testing.T) {
+	entry := mustCompile(t, AlertAllowListEntry{AlertName: "KubeAPIErrorBudgetBurn"})
+
+	alert := monitorapi.Alert{Name: "KubeAPIErrorBudgetBurn"}
+	if !entry.allows(alert, 0) {
+		t.Errorf("expected entry to allow an alert with the matching name")
+	}
+
+	other := monitorapi.Alert{Name: "SomethingElse"}
+	if entry.allows(other, 0) {
+		t.Errorf("expected entry not to allow an alert with a different name")
+	}
+}
+
+func TestAlertAllowListEntryAllowsChecksEachAlertsOwnDuration(t *testing.T) {
+	entry := mustCompile(t, AlertAllowListEntry{
+		AlertName:   "KubeAPIErrorBudgetBurn",
+		MaxDuration: 5 * time.Minute,
+	})
+	alert := monitorapi.Alert{Name: "KubeAPIErrorBudgetBurn"}
+
+	if !entry.allows(alert, 4*time.Minute) {
+		t.Errorf("expected entry to allow an alert that fired for less than MaxDuration")
+	}
+	if entry.allows(alert, 6*time.Minute) {
+		t.Errorf("expected entry to reject an alert that fired for longer than MaxDuration")
+	}
+
+	// A regression class already caught once in this series: allows must be
+	// checked against the duration the caller passes in for this specific
+	// alert, not some other, unrelated duration (e.g. the suite's overall
+	// run time), so a long suite doesn't make every short-duration alert
+	// look like it overstayed MaxDuration.
+	if entry.allows(alert, 0) == entry.allows(alert, 6*time.Minute) {
+		t.Errorf("allows should depend on the duration argument, not be constant regardless of it")
+	}
+}
+
+func TestAlertAllowListEntryAllowsZeroMaxDurationMeansNoLimit(t *testing.T) {
+	entry := mustCompile(t, AlertAllowListEntry{AlertName: "KubeAPIErrorBudgetBurn"})
+	alert := monitorapi.Alert{Name: "KubeAPIErrorBudgetBurn"}
+
+	if !entry.allows(alert, 24*time.Hour) {
+		t.Errorf("expected a zero MaxDuration to mean no limit is enforced")
+	}
+}
+
+func TestAlertAllowListEntryAllowsLabelMatchers(t *testing.T) {
+	entry := mustCompile(t, AlertAllowListEntry{
+		AlertName:     "KubeAPIErrorBudgetBurn",
+		LabelMatchers: map[string]string{"severity": "^warning$"},
+	})
+
+	warning := monitorapi.Alert{Name: "KubeAPIErrorBudgetBurn", Labels: map[string]string{"severity": "warning"}}
+	if !entry.allows(warning, 0) {
+		t.Errorf("expected entry to allow an alert whose labels match every matcher")
+	}
+
+	critical := monitorapi.Alert{Name: "KubeAPIErrorBudgetBurn", Labels: map[string]string{"severity": "critical"}}
+	if entry.allows(critical, 0) {
+		t.Errorf("expected entry to reject an alert whose label fails a matcher")
+	}
+
+	missing := monitorapi.Alert{Name: "KubeAPIErrorBudgetBurn"}
+	if entry.allows(missing, 0) {
+		t.Errorf("expected entry to reject an alert missing a label a matcher requires")
+	}
+}
+
+func TestAlertIsAllowedChecksEveryEntry(t *testing.T) {
+	allowList := &AlertAllowList{
+		Entries: []AlertAllowListEntry{
+			mustCompile(t, AlertAllowListEntry{AlertName: "AlertA", MaxDuration: time.Minute}),
+			mustCompile(t, AlertAllowListEntry{AlertName: "AlertB"}),
+		},
+	}
+
+	if !alertIsAllowed(allowList, monitorapi.Alert{Name: "AlertB"}, 24*time.Hour) {
+		t.Errorf("expected AlertB to be allowed regardless of duration")
+	}
+	if alertIsAllowed(allowList, monitorapi.Alert{Name: "AlertA"}, 2*time.Minute) {
+		t.Errorf("expected AlertA to be rejected once it exceeds its own MaxDuration")
+	}
+	if alertIsAllowed(allowList, monitorapi.Alert{Name: "AlertC"}, 0) {
+		t.Errorf("expected an alert with no matching entry to be rejected")
+	}
+}
+
+func TestAlertIsAllowedNilAllowListRejectsEverything(t *testing.T) {
+	if alertIsAllowed(nil, monitorapi.Alert{Name: "AnyAlert"}, 0) {
+		t.Errorf("expected a nil allow-list to reject every alert")
+	}
+}
@@ -0,0 +1,29 @@
+package synthetictests
+
+import (
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// EventIntervalTest is the shape shared by every synthetic invariant
+// (StableSystemEventInvariants, SystemEventInvariants, and
+// PrometheusAlertsInvariant), so they can all be passed to
+// ginkgo.JUnitForEventsFunc individually or merged with CombineEventTests.
+type EventIntervalTest func(events monitorapi.Intervals, duration time.Duration) []*junitapi.JUnitTestCase
+
+// CombineEventTests runs each of tests against the same events/duration and
+// concatenates their JUnit cases, so a suite can keep its existing system
+// event invariants while also gaining newer ones like
+// PrometheusAlertsInvariant without juggling multiple SyntheticEventTests
+// hooks.
+func CombineEventTests(tests ...EventIntervalTest) EventIntervalTest {
+	return func(events monitorapi.Intervals, duration time.Duration) []*junitapi.JUnitTestCase {
+		var cases []*junitapi.JUnitTestCase
+		for _, test := range tests {
+			cases = append(cases, test(events, duration)...)
+		}
+		return cases
+	}
+}
@@ -0,0 +1,102 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FlakeClassifier looks up a failed, fully-qualified test name against a
+// known-flakes registry and reports whether it should be retried before
+// the suite decides pass/fail. It exists so a single chronic flake can be
+// retried on its own terms instead of being hidden behind a suite-wide
+// MaximumAllowedFlakes count that also masks unrelated regressions.
+type FlakeClassifier interface {
+	// Classify returns the retry policy for name and true if name matched
+	// a non-expired entry in the registry.
+	Classify(name string) (FlakeEntry, bool)
+}
+
+// FlakeEntry is one record in a known-flakes registry.
+type FlakeEntry struct {
+	Name       string    `json:"name,omitempty"`
+	Pattern    string    `json:"pattern,omitempty"`
+	MaxRetries int       `json:"max_retries"`
+	Bug        string    `json:"bug"`
+	Expires    time.Time `json:"expires"`
+
+	re *regexp.Regexp
+}
+
+func (e *FlakeEntry) matches(name string) bool {
+	if e.Pattern != "" {
+		return e.re.MatchString(name)
+	}
+	return e.Name == name
+}
+
+// flakeRegistry is a FlakeClassifier backed by a static list of entries,
+// as loaded from a JSON known-flakes file or URL.
+type flakeRegistry struct {
+	entries []FlakeEntry
+}
+
+func (r *flakeRegistry) Classify(name string) (FlakeEntry, bool) {
+	now := time.Now()
+	for _, entry := range r.entries {
+		if !entry.Expires.IsZero() && now.After(entry.Expires) {
+			continue
+		}
+		if entry.matches(name) {
+			return entry, true
+		}
+	}
+	return FlakeEntry{}, false
+}
+
+// LoadFlakeClassifier reads a known-flakes registry from a local path or,
+// if source starts with "http://" or "https://", fetches it over HTTP. Each
+// entry's pattern, if set, is compiled as a regular expression up front so
+// Classify never fails.
+func LoadFlakeClassifier(source string) (FlakeClassifier, error) {
+	data, err := readFlakeSource(source)
+	if err != nil {
+		return nil, fmt.Errorf("loading flake registry %s: %w", source, err)
+	}
+
+	var entries []FlakeEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing flake registry %s: %w", source, err)
+	}
+	for i := range entries {
+		if entries[i].Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(entries[i].Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("flake registry %s, entry %d: invalid pattern %q: %w", source, i, entries[i].Pattern, err)
+		}
+		entries[i].re = re
+	}
+	return &flakeRegistry{entries: entries}, nil
+}
+
+func readFlakeSource(source string) ([]byte, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := http.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	}
+	return os.ReadFile(source)
+}
@@ -0,0 +1,73 @@
+package ginkgo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FlakeRetrier consults a FlakeClassifier to decide whether a failed test
+// should be retried instead of reported as a failure outright, and keeps
+// a running count of how many tests it has retried so a suite summary can
+// surface it as "flake-retried".
+type FlakeRetrier struct {
+	Classifier FlakeClassifier
+
+	// Retried is the number of distinct tests this retrier has rerun at
+	// least once.
+	Retried int
+}
+
+// NewFlakeRetrier returns a FlakeRetrier backed by classifier. A nil
+// classifier is valid and makes Resolve a no-op, so callers don't need to
+// special-case "--flake-db wasn't set".
+func NewFlakeRetrier(classifier FlakeClassifier) *FlakeRetrier {
+	return &FlakeRetrier{Classifier: classifier}
+}
+
+// Resolve decides the final outcome of a failed test. If the classifier
+// doesn't recognize it, failed is returned unchanged. Otherwise, rerun is
+// invoked up to the matched entry's MaxRetries times, stopping as soon as
+// one attempt passes; the returned TestResult carries the final pass/fail
+// and an Output string summarizing the retry history and linked bug,
+// ready to be rendered as the JUnit case's <system-out>.
+func (r *FlakeRetrier) Resolve(failed TestResult, rerun func() (TestResult, error)) (TestResult, error) {
+	if r == nil || r.Classifier == nil {
+		return failed, nil
+	}
+	entry, ok := r.Classifier.Classify(failed.Name)
+	if !ok {
+		return failed, nil
+	}
+
+	attempts := []TestResult{failed}
+	for i := 0; i < entry.MaxRetries && !attempts[len(attempts)-1].Passed; i++ {
+		result, err := rerun()
+		if err != nil {
+			return failed, fmt.Errorf("retrying %s: %w", failed.Name, err)
+		}
+		if len(attempts) == 1 {
+			r.Retried++
+		}
+		attempts = append(attempts, result)
+	}
+
+	final := attempts[len(attempts)-1]
+	final.Name = failed.Name
+	final.Output = retrySystemOut(entry, attempts)
+	return final, nil
+}
+
+// retrySystemOut renders the retry history and linked bug as the
+// <system-out> text for the final JUnit case.
+func retrySystemOut(entry FlakeEntry, attempts []TestResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "flake-retried: known flake (bug: %s)\n", entry.Bug)
+	for i, attempt := range attempts {
+		status := "failed"
+		if attempt.Passed {
+			status = "passed"
+		}
+		fmt.Fprintf(&b, "  attempt %d: %s\n", i+1, status)
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package ginkgo
+
+import (
+	"strings"
+	"testing"
+)
+
+type staticClassifier map[string]FlakeEntry
+
+func (c staticClassifier) Classify(name string) (FlakeEntry, bool) {
+	entry, ok := c[name]
+	return entry, ok
+}
+
+func TestFlakeRetrierResolvePassesOnRetry(t *testing.T) {
+	retrier := NewFlakeRetrier(staticClassifier{
+		"flaky test": {MaxRetries: 2, Bug: "BZ-123"},
+	})
+
+	calls := 0
+	result, err := retrier.Resolve(TestResult{Name: "flaky test", Passed: false}, func() (TestResult, error) {
+		calls++
+		return TestResult{Name: "flaky test", Passed: calls == 2}, nil
+	})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if !result.Passed {
+		t.Errorf("expected the test to pass after a retry, got failed result: %+v", result)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 reruns before passing, got %d", calls)
+	}
+	if retrier.Retried != 1 {
+		t.Errorf("expected Retried to be 1, got %d", retrier.Retried)
+	}
+	if !strings.Contains(result.Output, "BZ-123") {
+		t.Errorf("expected system-out to mention the linked bug, got %q", result.Output)
+	}
+	if !strings.Contains(result.Output, "attempt 3: passed") {
+		t.Errorf("expected system-out to record the passing attempt, got %q", result.Output)
+	}
+}
+
+func TestFlakeRetrierResolveExhaustsRetries(t *testing.T) {
+	retrier := NewFlakeRetrier(staticClassifier{
+		"always flaky": {MaxRetries: 1, Bug: "BZ-456"},
+	})
+
+	calls := 0
+	result, err := retrier.Resolve(TestResult{Name: "always flaky", Passed: false}, func() (TestResult, error) {
+		calls++
+		return TestResult{Name: "always flaky", Passed: false}, nil
+	})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if result.Passed {
+		t.Errorf("expected the test to remain failed, got %+v", result)
+	}
+	if calls != 1 {
+		t.Errorf("expected exactly MaxRetries (1) reruns, got %d", calls)
+	}
+}
+
+func TestFlakeRetrierResolveZeroMaxRetriesDoesNotCountAsRetried(t *testing.T) {
+	retrier := NewFlakeRetrier(staticClassifier{
+		"known but unretryable": {MaxRetries: 0, Bug: "BZ-789"},
+	})
+
+	failed := TestResult{Name: "known but unretryable", Passed: false}
+	result, err := retrier.Resolve(failed, func() (TestResult, error) {
+		t.Fatal("rerun should not be called when MaxRetries is 0")
+		return TestResult{}, nil
+	})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if result.Passed {
+		t.Errorf("expected the test to remain failed, got %+v", result)
+	}
+	if retrier.Retried != 0 {
+		t.Errorf("expected Retried to stay 0 since no rerun ever happened, got %d", retrier.Retried)
+	}
+}
+
+func TestFlakeRetrierResolveUnknownTestIsUnchanged(t *testing.T) {
+	retrier := NewFlakeRetrier(staticClassifier{})
+
+	failed := TestResult{Name: "not a known flake", Passed: false}
+	result, err := retrier.Resolve(failed, func() (TestResult, error) {
+		t.Fatal("rerun should not be called for an unclassified test")
+		return TestResult{}, nil
+	})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if result != failed {
+		t.Errorf("expected an unclassified failure to pass through unchanged, got %+v", result)
+	}
+	if retrier.Retried != 0 {
+		t.Errorf("expected Retried to stay 0, got %d", retrier.Retried)
+	}
+}
+
+func TestFlakeRetrierResolveNilClassifierIsNoop(t *testing.T) {
+	retrier := NewFlakeRetrier(nil)
+	failed := TestResult{Name: "anything", Passed: false}
+	result, err := retrier.Resolve(failed, func() (TestResult, error) {
+		t.Fatal("rerun should not be called with a nil classifier")
+		return TestResult{}, nil
+	})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if result != failed {
+		t.Errorf("expected the failure to pass through unchanged, got %+v", result)
+	}
+}
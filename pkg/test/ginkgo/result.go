@@ -0,0 +1,14 @@
+package ginkgo
+
+import "time"
+
+// TestResult captures the outcome of running a single test, independent of
+// how it was invoked (initial run or retry), so callers like TestSuite's
+// PostFailure hook and the flake retry logic can reason about failures
+// uniformly.
+type TestResult struct {
+	Name     string
+	Passed   bool
+	Output   string
+	Duration time.Duration
+}
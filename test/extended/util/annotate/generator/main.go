@@ -0,0 +1,41 @@
+// Command generator walks every ginkgo test name known to openshift-tests
+// and writes test/extended/util/annotate/generated, the file that
+// staticSuites relies on to filter tests into suites. Run it via
+// `make update-generated-annotations` whenever rules_data.go changes or upstream
+// renames/removes tests.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/openshift/origin/test/extended/util/annotate"
+)
+
+func main() {
+	allowUnusedAnnotations := flag.Bool("allow-unused-annotations", false, "do not fail the generator when a label=>pattern rule matches zero tests")
+	flag.Parse()
+
+	names, err := listTestNames()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	annotations, err := annotate.Annotate(names, rules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if unused := annotate.ReportUnusedRules(os.Stderr, rules); unused > 0 && !*allowUnusedAnnotations {
+		fmt.Fprintf(os.Stderr, "%d annotation rule(s) matched no test; pass --allow-unused-annotations to continue anyway\n", unused)
+		os.Exit(1)
+	}
+
+	if err := writeGenerated(annotations); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
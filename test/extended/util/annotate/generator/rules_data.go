@@ -0,0 +1,18 @@
+package main
+
+import "github.com/openshift/origin/test/extended/util/annotate"
+
+// rules drives the labels applied to test/extended/util/annotate/generated.
+// Keep entries ordered roughly by how broad the pattern is, narrowest last,
+// so the stderr report from an --allow-unused-annotations=false run reads
+// top-to-bottom like the suites it affects.
+var rules = []*annotate.Rule{
+	{Label: "[Suite:openshift/conformance/parallel]", Pattern: "[Conformance]"},
+	{Label: "[Suite:openshift/conformance/serial]", Pattern: "[Serial]"},
+	{Label: "[Feature:Builds]", Pattern: "[sig-builds]"},
+	{Label: "[Feature:Templates]", Pattern: "[sig-templates]"},
+	{Label: "[Feature:ImageEcosystem]", Pattern: "[sig-imageregistry] ImageEcosystem"},
+	{Label: "[Feature:Jenkins]", Pattern: "[Feature:Jenkins]"},
+	{Label: "[Feature:EtcdRecovery]", Pattern: `^\[sig-etcd\].*disaster recovery`, Regex: true},
+	{Label: "[Feature:EtcdVerticalScaling]", Pattern: `^\[sig-etcd\].*vertical scaling`, Regex: true},
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// listTestNames builds openshift-tests and asks it to dry-run every spec so
+// we get the exact, fully-qualified test names that rules_data.go's patterns
+// are written against. This mirrors how the binary itself enumerates tests at
+// runtime, so a rule that matches here is guaranteed to match in the field.
+func listTestNames() ([]string, error) {
+	binary, err := buildTestBinary()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(binary)
+
+	var out bytes.Buffer
+	cmd := exec.Command(binary, "run", "all", "--dry-run")
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("listing test names: %w", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+func buildTestBinary() (string, error) {
+	tmp, err := os.CreateTemp("", "openshift-tests-generator-")
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+
+	cmd := exec.Command("go", "build", "-o", tmp.Name(), "github.com/openshift/origin/cmd/openshift-tests")
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("building openshift-tests: %w", err)
+	}
+	return tmp.Name(), nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+)
+
+const generatedHeader = `// Code generated by test/extended/util/annotate/generator. DO NOT EDIT.
+
+package generated
+
+// Annotations maps a fully-qualified ginkgo test name to the suite/feature
+// labels that should be appended to it.
+var Annotations = map[string]string{
+`
+
+// writeGenerated renders annotations as the generated Go file imported by
+// cmd/openshift-tests and writes it to
+// test/extended/util/annotate/generated/zz_generated_annotations.go.
+func writeGenerated(annotations map[string]string) error {
+	names := make([]string, 0, len(annotations))
+	for name := range annotations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString(generatedHeader)
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%q: %q,\n", name, annotations[name])
+	}
+	buf.WriteString("}\n")
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated annotations: %w", err)
+	}
+
+	const outDir = "test/extended/util/annotate/generated"
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", outDir, err)
+	}
+	return os.WriteFile(outDir+"/zz_generated_annotations.go", formatted, 0o644)
+}
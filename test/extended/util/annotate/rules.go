@@ -0,0 +1,105 @@
+// Package annotate implements the rule matching used to generate
+// test/extended/util/annotate/generated, the file that maps upstream and
+// in-repo ginkgo test names to the OpenShift suite/feature labels that
+// staticSuites filters on (e.g. "[Suite:openshift/conformance/parallel]").
+package annotate
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Rule associates a label (typically a bracketed tag like
+// "[Suite:openshift/conformance/parallel]") with a pattern used to select
+// the test names that should receive it. Patterns are plain substrings by
+// default; set Regex to treat Pattern as a regular expression.
+type Rule struct {
+	Label   string
+	Pattern string
+	Regex   bool
+
+	// matched is set once this rule has matched at least one test name
+	// during a call to Annotate. It lets callers detect rules that are
+	// left over after the tests they used to target were renamed or
+	// removed upstream.
+	matched bool
+
+	re *regexp.Regexp
+}
+
+// compile lazily builds the regexp backing a Regex rule. Substring rules
+// have nothing to compile.
+func (r *Rule) compile() error {
+	if !r.Regex || r.re != nil {
+		return nil
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("rule %q has invalid pattern %q: %w", r.Label, r.Pattern, err)
+	}
+	r.re = re
+	return nil
+}
+
+// matches reports whether the rule's pattern selects the given test name.
+func (r *Rule) matches(name string) (bool, error) {
+	if err := r.compile(); err != nil {
+		return false, err
+	}
+	if r.Regex {
+		return r.re.MatchString(name), nil
+	}
+	return strings.Contains(name, r.Pattern), nil
+}
+
+// Annotate applies rules, in order, to every name in testNames and returns
+// the label suffix that should be appended to each matching test name. Each
+// rule's matched state is updated as a side effect so that UnusedRules can
+// report rules that never fired.
+func Annotate(testNames []string, rules []*Rule) (map[string]string, error) {
+	annotations := make(map[string]string, len(testNames))
+	for _, name := range testNames {
+		var labels []string
+		for _, rule := range rules {
+			ok, err := rule.matches(name)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			rule.matched = true
+			labels = append(labels, rule.Label)
+		}
+		if len(labels) > 0 {
+			annotations[name] = " " + strings.Join(labels, " ")
+		}
+	}
+	return annotations, nil
+}
+
+// UnusedRules returns the rules that did not match any test name in any
+// call to Annotate so far, in the order they were provided. Annotate never
+// clears a rule's matched state.
+func UnusedRules(rules []*Rule) []*Rule {
+	var unused []*Rule
+	for _, rule := range rules {
+		if !rule.matched {
+			unused = append(unused, rule)
+		}
+	}
+	return unused
+}
+
+// ReportUnusedRules writes one "label => pattern" line per unused rule to
+// out. It returns the number of rules reported so callers can decide
+// whether to fail the generator run.
+func ReportUnusedRules(out io.Writer, rules []*Rule) int {
+	unused := UnusedRules(rules)
+	for _, rule := range unused {
+		fmt.Fprintf(out, "%s => %s\n", rule.Label, rule.Pattern)
+	}
+	return len(unused)
+}
@@ -0,0 +1,84 @@
+package annotate
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAnnotateMatchedRule(t *testing.T) {
+	rules := []*Rule{
+		{Label: "[Suite:openshift/conformance/parallel]", Pattern: "[sig-auth]"},
+	}
+	names := []string{
+		`[sig-auth] should create a token`,
+		`[sig-storage] should mount a volume`,
+	}
+
+	annotations, err := Annotate(names, rules)
+	if err != nil {
+		t.Fatalf("Annotate returned error: %v", err)
+	}
+
+	if got, want := annotations[names[0]], " [Suite:openshift/conformance/parallel]"; got != want {
+		t.Errorf("annotations[%q] = %q, want %q", names[0], got, want)
+	}
+	if _, ok := annotations[names[1]]; ok {
+		t.Errorf("annotations[%q] should not be set", names[1])
+	}
+	if len(UnusedRules(rules)) != 0 {
+		t.Errorf("expected rule to be marked matched, got unused: %v", UnusedRules(rules))
+	}
+}
+
+func TestAnnotateUnmatchedRuleIsReported(t *testing.T) {
+	rules := []*Rule{
+		{Label: "[Suite:openshift/conformance/parallel]", Pattern: "[sig-auth]"},
+		{Label: "[Disabled:Stale]", Pattern: "[sig-deprecated] should do a thing nobody runs anymore"},
+	}
+	names := []string{`[sig-auth] should create a token`}
+
+	if _, err := Annotate(names, rules); err != nil {
+		t.Fatalf("Annotate returned error: %v", err)
+	}
+
+	unused := UnusedRules(rules)
+	if len(unused) != 1 || unused[0].Label != "[Disabled:Stale]" {
+		t.Fatalf("expected exactly the stale rule to be unused, got: %v", unused)
+	}
+
+	var buf bytes.Buffer
+	count := ReportUnusedRules(&buf, rules)
+	if count != 1 {
+		t.Fatalf("ReportUnusedRules returned %d, want 1", count)
+	}
+	want := "[Disabled:Stale] => [sig-deprecated] should do a thing nobody runs anymore\n"
+	if buf.String() != want {
+		t.Errorf("ReportUnusedRules wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestAnnotateRegexVsSubstringRule(t *testing.T) {
+	rules := []*Rule{
+		{Label: "[Literal]", Pattern: "[Feature:Foo]"},
+		{Label: "[Regex]", Pattern: `^\[sig-network\].*should drop$`, Regex: true},
+	}
+	names := []string{
+		`[sig-network] should drop invalid packets [Feature:Foo]`,
+		`prefix [sig-network] should drop invalid packets`,
+	}
+
+	annotations, err := Annotate(names, rules)
+	if err != nil {
+		t.Fatalf("Annotate returned error: %v", err)
+	}
+
+	if annotations[names[0]] != " [Literal]" {
+		t.Errorf("expected only the literal rule to match %q, got %q", names[0], annotations[names[0]])
+	}
+	if _, ok := annotations[names[1]]; ok {
+		t.Errorf("regex rule should not match %q since it is anchored to the start", names[1])
+	}
+	if len(UnusedRules(rules)) != 1 || UnusedRules(rules)[0].Label != "[Regex]" {
+		t.Errorf("expected the regex rule to be unused, got: %v", UnusedRules(rules))
+	}
+}